@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"strconv"
 	"time"
@@ -8,26 +9,25 @@ import (
 	"github.com/social9/go-queues/config"
 	"github.com/social9/go-queues/streams/sqs"
 
-	"github.com/aws/aws-sdk-go/aws"
-	awsSqs "github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	awsSqs "github.com/aws/aws-sdk-go-v2/service/sqs/types"
 )
 
 func main() {
 	env := config.Env()
+	ctx := context.Background()
 
-	// Instantiate the queue with service connection
-	queue, _ := sqs.NewSQS(sqs.Config{
-		// aws config
-		AWSRegion:  env.AWSRegion,
-		MaxRetries: 10,
-
-		// aws creds - if provided, env is temporarily updated. Or you can add to env yourself
-		AWSKey:    env.AWSKey,
-		AWSSecret: env.AWSSecret,
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(env.AWSRegion))
+	if err != nil {
+		log.Fatal(err)
+	}
 
+	// Instantiate the queue with service connection
+	queue, _ := sqs.NewSQS(ctx, awsCfg, sqs.Config{
 		// sqs config
 		URL:               env.SQSURL,
-		BatchSize:         env.SQSBatchSize,
+		BatchSize:         int32(env.SQSLimit),
 		VisibilityTimeout: 120,
 		WaitSeconds:       20,
 
@@ -39,7 +39,7 @@ func main() {
 	})
 
 	// Simlulate sending the messages in batch
-	queue.Enqueue(getMessagesToEnque())
+	queue.Enqueue(ctx, getMessagesToEnque())
 
 	// simulate processing a request for 2 seconds
 	queue.RegisterPollHandler(func(msg *awsSqs.Message) {
@@ -54,19 +54,19 @@ func main() {
 		log.Println("Finished:", *msg.MessageId)
 
 		// Send back to the queue
-		queue.Delete(msg)
+		queue.Delete(ctx, msg)
 	})
 
 	// Poll from the SQS queue
-	queue.Poll()
+	queue.Poll(ctx)
 }
 
-func getMessagesToEnque() []*awsSqs.SendMessageBatchRequestEntry {
+func getMessagesToEnque() []awsSqs.SendMessageBatchRequestEntry {
 	msgs := []string{"Test message 1-1", "Test Message 2-1", "Test Message 3-1"}
 
-	var msgBatch []*awsSqs.SendMessageBatchRequestEntry
+	var msgBatch []awsSqs.SendMessageBatchRequestEntry
 	for i := 0; i < len(msgs); i++ {
-		message := &awsSqs.SendMessageBatchRequestEntry{
+		message := awsSqs.SendMessageBatchRequestEntry{
 			Id:                     aws.String(`test_` + strconv.Itoa(i)),
 			MessageBody:            aws.String(msgs[i]),
 			MessageDeduplicationId: aws.String(`dedup_` + strconv.Itoa(i)),
@@ -0,0 +1,113 @@
+package sqs
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// maxBatchEntries is the largest number of entries SQS accepts in a single
+// DeleteMessageBatch / ChangeMessageVisibilityBatch call.
+const maxBatchEntries = 10
+
+// VisibilityChange pairs a received message with the visibility timeout (in
+// seconds) it should be changed to, for use with ChangeVisibilityBatch.
+type VisibilityChange struct {
+	Message *types.Message
+	Seconds int32
+}
+
+// batchEntryID derives a batch entry Id from a message's receipt handle, since
+// SQS requires each entry in a batch call to carry a unique Id.
+func batchEntryID(receiptHandle string) string {
+	h := fnv.New32a()
+	h.Write([]byte(receiptHandle))
+	return strconv.FormatUint(uint64(h.Sum32()), 36)
+}
+
+// DeleteBatch deletes msgs via DeleteMessageBatch, splitting into chunks of
+// maxBatchEntries as needed. successful and failed are the MessageIds of
+// entries SQS reported back for each status.
+func (s *Config) DeleteBatch(ctx context.Context, msgs []*types.Message) (successful, failed []string, err error) {
+	for start := 0; start < len(msgs); start += maxBatchEntries {
+		end := start + maxBatchEntries
+		if end > len(msgs) {
+			end = len(msgs)
+		}
+		chunk := msgs[start:end]
+
+		entries := make([]types.DeleteMessageBatchRequestEntry, len(chunk))
+		idToMessageID := make(map[string]string, len(chunk))
+		for i, msg := range chunk {
+			id := batchEntryID(*msg.ReceiptHandle)
+			entries[i] = types.DeleteMessageBatchRequestEntry{
+				Id:            &id,
+				ReceiptHandle: msg.ReceiptHandle,
+			}
+			idToMessageID[id] = *msg.MessageId
+		}
+
+		out, batchErr := s.svc.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{
+			QueueUrl: &s.URL,
+			Entries:  entries,
+		})
+		if batchErr != nil {
+			err = batchErr
+			continue
+		}
+
+		for _, r := range out.Successful {
+			successful = append(successful, idToMessageID[*r.Id])
+		}
+		for _, r := range out.Failed {
+			failed = append(failed, idToMessageID[*r.Id])
+		}
+	}
+
+	return successful, failed, err
+}
+
+// ChangeVisibilityBatch changes the visibility timeout of several messages via
+// ChangeMessageVisibilityBatch, splitting into chunks of maxBatchEntries as needed.
+func (s *Config) ChangeVisibilityBatch(ctx context.Context, changes []VisibilityChange) (successful, failed []string, err error) {
+	for start := 0; start < len(changes); start += maxBatchEntries {
+		end := start + maxBatchEntries
+		if end > len(changes) {
+			end = len(changes)
+		}
+		chunk := changes[start:end]
+
+		entries := make([]types.ChangeMessageVisibilityBatchRequestEntry, len(chunk))
+		idToMessageID := make(map[string]string, len(chunk))
+		for i, change := range chunk {
+			id := batchEntryID(*change.Message.ReceiptHandle)
+			entries[i] = types.ChangeMessageVisibilityBatchRequestEntry{
+				Id:                &id,
+				ReceiptHandle:     change.Message.ReceiptHandle,
+				VisibilityTimeout: change.Seconds,
+			}
+			idToMessageID[id] = *change.Message.MessageId
+		}
+
+		out, batchErr := s.svc.ChangeMessageVisibilityBatch(ctx, &sqs.ChangeMessageVisibilityBatchInput{
+			QueueUrl: &s.URL,
+			Entries:  entries,
+		})
+		if batchErr != nil {
+			err = batchErr
+			continue
+		}
+
+		for _, r := range out.Successful {
+			successful = append(successful, idToMessageID[*r.Id])
+		}
+		for _, r := range out.Failed {
+			failed = append(failed, idToMessageID[*r.Id])
+		}
+	}
+
+	return successful, failed, err
+}
@@ -0,0 +1,104 @@
+package sqs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// coalescer buffers Delete and ChangeVisibilityTimeout calls on behalf of a
+// Config with CoalesceBatches set, flushing them via DeleteBatch /
+// ChangeVisibilityBatch every FlushInterval or once maxBatchEntries accumulate.
+type coalescer struct {
+	cfg *Config
+
+	deleteMu  sync.Mutex
+	deleteBuf []*types.Message
+
+	visMu  sync.Mutex
+	visBuf []VisibilityChange
+}
+
+// coalescerFor lazily starts the background flusher the first time it's
+// needed, tied to ctx's lifetime.
+func (s *Config) coalescerFor(ctx context.Context) *coalescer {
+	s.coalescerOnce.Do(func() {
+		s.coalescer = &coalescer{cfg: s}
+		go s.coalescer.run(ctx)
+	})
+
+	return s.coalescer
+}
+
+func (c *coalescer) run(ctx context.Context) {
+	ticker := time.NewTicker(c.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.flushDeletes(ctx)
+			c.flushVisibilityChanges(ctx)
+			return
+		case <-ticker.C:
+			c.flushDeletes(ctx)
+			c.flushVisibilityChanges(ctx)
+		}
+	}
+}
+
+func (c *coalescer) enqueueDelete(msg *types.Message) {
+	c.deleteMu.Lock()
+	c.deleteBuf = append(c.deleteBuf, msg)
+	full := len(c.deleteBuf) >= maxBatchEntries
+	c.deleteMu.Unlock()
+
+	if full {
+		c.flushDeletes(context.Background())
+	}
+}
+
+func (c *coalescer) enqueueVisibilityChange(msg *types.Message, seconds int32) {
+	c.visMu.Lock()
+	c.visBuf = append(c.visBuf, VisibilityChange{Message: msg, Seconds: seconds})
+	full := len(c.visBuf) >= maxBatchEntries
+	c.visMu.Unlock()
+
+	if full {
+		c.flushVisibilityChanges(context.Background())
+	}
+}
+
+func (c *coalescer) flushDeletes(ctx context.Context) {
+	c.deleteMu.Lock()
+	batch := c.deleteBuf
+	c.deleteBuf = nil
+	c.deleteMu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	successful, failed, err := c.cfg.DeleteBatch(ctx, batch)
+	if c.cfg.OnBatchResult != nil {
+		c.cfg.OnBatchResult("delete", successful, failed, err)
+	}
+}
+
+func (c *coalescer) flushVisibilityChanges(ctx context.Context) {
+	c.visMu.Lock()
+	batch := c.visBuf
+	c.visBuf = nil
+	c.visMu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	successful, failed, err := c.cfg.ChangeVisibilityBatch(ctx, batch)
+	if c.cfg.OnBatchResult != nil {
+		c.cfg.OnBatchResult("changeVisibility", successful, failed, err)
+	}
+}
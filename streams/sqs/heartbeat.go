@@ -0,0 +1,67 @@
+package sqs
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// WithHeartbeat wraps handler so that, while it runs, a companion goroutine
+// periodically extends the message's visibility timeout by VisibilityTimeout
+// seconds - keeping SQS from redelivering it out from under a long-running
+// handler. The heartbeat stops as soon as handler returns, or once
+// maxProcessingTime elapses, whichever comes first; past that point SQS is
+// left to reclaim the message as usual. A maxProcessingTime of 0 means no cap.
+//
+// handler receives a ctx that is cancelled once maxProcessingTime elapses, so
+// it can check ctx.Done() to bail out when the cap is exceeded. A heartbeat that
+// stops early because ChangeMessageVisibility failed does not cancel ctx.
+func (s *Config) WithHeartbeat(maxProcessingTime time.Duration, handler func(ctx context.Context, msg *types.Message)) func(msg *types.Message) {
+	return func(msg *types.Message) {
+		parent := context.Background()
+		if maxProcessingTime > 0 {
+			var cancelTimeout context.CancelFunc
+			parent, cancelTimeout = context.WithTimeout(parent, maxProcessingTime)
+			defer cancelTimeout()
+		}
+
+		ctx, cancel := context.WithCancel(parent)
+		defer cancel()
+
+		go s.heartbeat(ctx, msg)
+
+		handler(ctx, msg)
+	}
+}
+
+func (s *Config) heartbeat(ctx context.Context, msg *types.Message) {
+	interval := time.Duration(s.VisibilityTimeout/2) * time.Second
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := s.svc.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+				QueueUrl:          &s.URL,
+				ReceiptHandle:     msg.ReceiptHandle,
+				VisibilityTimeout: s.VisibilityTimeout,
+			})
+			if err != nil {
+				// eg MessageNotInflight - the message was already deleted or
+				// its visibility already expired. Nothing more we can do.
+				logger.Printf("heartbeat: failed to extend visibility for %s, stopping: %s", *msg.MessageId, err)
+				return
+			}
+			logger.Printf("heartbeat: extended visibility for %s by %ds", *msg.MessageId, s.VisibilityTimeout)
+		}
+	}
+}
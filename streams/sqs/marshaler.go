@@ -0,0 +1,48 @@
+package sqs
+
+import (
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// Marshaler converts a typed payload into an SQS message body, plus any
+// message attributes the wire format needs (eg a schema id). Implementations
+// are used by Publisher.
+type Marshaler interface {
+	Marshal(v interface{}) (body string, attrs map[string]types.MessageAttributeValue, err error)
+}
+
+// Unmarshaler is the inverse of Marshaler, reconstructing v from an SQS
+// message body and its attributes. Implementations are used by Subscriber.
+type Unmarshaler interface {
+	Unmarshal(body string, attrs map[string]types.MessageAttributeValue, v interface{}) error
+}
+
+// JSONMarshaler is the default Marshaler/Unmarshaler, encoding payloads as
+// plain JSON with no extra message attributes.
+type JSONMarshaler struct{}
+
+// Marshal encodes v as JSON.
+func (JSONMarshaler) Marshal(v interface{}) (string, map[string]types.MessageAttributeValue, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return string(body), nil, nil
+}
+
+// Unmarshal decodes a JSON body into v. attrs is ignored.
+func (JSONMarshaler) Unmarshal(body string, attrs map[string]types.MessageAttributeValue, v interface{}) error {
+	return json.Unmarshal([]byte(body), v)
+}
+
+// stringAttr builds a String-typed SQS message attribute.
+func stringAttr(value string) types.MessageAttributeValue {
+	return types.MessageAttributeValue{
+		DataType:    aws.String("String"),
+		StringValue: aws.String(value),
+	}
+}
@@ -0,0 +1,47 @@
+package sqs
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// Message is a typed envelope delivered by Subscriber. Body holds the raw,
+// still-marshaled payload; callers decode it with the same Unmarshaler the
+// Subscriber was built with (Subscriber.Unmarshal). Metadata carries every
+// message attribute that has a StringValue, including any the Marshaler itself
+// wrote; attributes without one are left out.
+type Message struct {
+	ID       string
+	Body     []byte
+	Metadata map[string]string
+
+	queueURL      string
+	receiptHandle string
+	rawAttrs      map[string]types.MessageAttributeValue
+	svc           *sqs.Client
+}
+
+// Ack deletes the message from the queue, marking it as processed.
+func (m *Message) Ack(ctx context.Context) error {
+	_, err := m.svc.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      &m.queueURL,
+		ReceiptHandle: &m.receiptHandle,
+	})
+
+	return err
+}
+
+// Nack makes the message immediately visible again so it is redelivered,
+// by zeroing its visibility timeout.
+func (m *Message) Nack(ctx context.Context) error {
+	var zero int32
+	_, err := m.svc.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          &m.queueURL,
+		ReceiptHandle:     &m.receiptHandle,
+		VisibilityTimeout: zero,
+	})
+
+	return err
+}
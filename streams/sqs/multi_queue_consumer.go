@@ -0,0 +1,228 @@
+package sqs
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// QueueConfigAttributes describes the queue to create when a QueueSpec's
+// queue is missing. See CreateQueueIfMissing.
+type QueueConfigAttributes struct {
+	VisibilityTimeout int32
+
+	// DeadLetterTargetArn, if set, configures a redrive policy pointing at
+	// that DLQ after MaxReceiveCount delivery attempts.
+	DeadLetterTargetArn string
+	MaxReceiveCount     int32
+
+	FIFO     bool
+	KMSKeyID string
+}
+
+// QueueSpec configures one queue polled by a MultiQueueConsumer.
+type QueueSpec struct {
+	// URL of the queue to poll. If empty, Name is resolved to a URL via
+	// LookupQueueURL (or CreateQueueIfMissing, when QueueConfigAttributes is set).
+	URL  string
+	Name string
+
+	// QueueConfigAttributes, if set, causes the queue to be created with
+	// these attributes when it doesn't already exist.
+	QueueConfigAttributes *QueueConfigAttributes
+
+	BatchSize         int32
+	WaitSeconds       int32
+	VisibilityTimeout int32
+	RunInterval       int
+
+	// Weight caps how many of this queue's messages may be handled
+	// concurrently, independent of the other queues sharing MaxHandlers.
+	// Zero means no queue-local cap beyond the consumer's MaxHandlers.
+	Weight int
+
+	Handler func(msg *types.Message)
+}
+
+// MultiQueueConsumer polls several SQS queues from a single process, sharing
+// a global MaxHandlers budget across them.
+type MultiQueueConsumer struct {
+	svc         *sqs.Client
+	MaxHandlers int
+
+	// OnError, if set, is called with every ReceiveMessage error any of the
+	// polled queues hits.
+	OnError func(error)
+
+	sem chan struct{}
+}
+
+// NewMultiQueueConsumer builds a MultiQueueConsumer backed by awsCfg. Across
+// all queues it serves, at most maxHandlers messages are handled concurrently.
+func NewMultiQueueConsumer(awsCfg aws.Config, maxHandlers int) *MultiQueueConsumer {
+	return &MultiQueueConsumer{
+		svc:         sqs.NewFromConfig(awsCfg),
+		MaxHandlers: maxHandlers,
+		sem:         make(chan struct{}, maxHandlers),
+	}
+}
+
+// LookupQueueURL resolves a queue name to its URL.
+func (m *MultiQueueConsumer) LookupQueueURL(ctx context.Context, name string) (string, error) {
+	out, err := m.svc.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: &name})
+	if err != nil {
+		return "", err
+	}
+
+	return *out.QueueUrl, nil
+}
+
+// CreateQueueIfMissing resolves name to a URL, creating the queue with attrs
+// first if it doesn't already exist.
+func (m *MultiQueueConsumer) CreateQueueIfMissing(ctx context.Context, name string, attrs QueueConfigAttributes) (string, error) {
+	if attrs.FIFO && !strings.HasSuffix(name, ".fifo") {
+		name += ".fifo"
+	}
+
+	if url, err := m.LookupQueueURL(ctx, name); err == nil {
+		return url, nil
+	}
+
+	queueAttrs := map[string]string{
+		"VisibilityTimeout": strconv.Itoa(int(attrs.VisibilityTimeout)),
+	}
+	if attrs.DeadLetterTargetArn != "" {
+		queueAttrs["RedrivePolicy"] = fmt.Sprintf(
+			`{"deadLetterTargetArn":"%s","maxReceiveCount":"%d"}`,
+			attrs.DeadLetterTargetArn, attrs.MaxReceiveCount,
+		)
+	}
+	if attrs.KMSKeyID != "" {
+		queueAttrs["KmsMasterKeyId"] = attrs.KMSKeyID
+	}
+	if attrs.FIFO {
+		queueAttrs["FifoQueue"] = "true"
+	}
+
+	out, err := m.svc.CreateQueue(ctx, &sqs.CreateQueueInput{
+		QueueName:  &name,
+		Attributes: queueAttrs,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return *out.QueueUrl, nil
+}
+
+// Run resolves every spec's queue URL, then polls all of them concurrently
+// until ctx is cancelled. It blocks until every queue's poll loop returns.
+func (m *MultiQueueConsumer) Run(ctx context.Context, specs []QueueSpec) error {
+	resolved := make([]QueueSpec, len(specs))
+	for i, spec := range specs {
+		if spec.URL == "" {
+			var (
+				url string
+				err error
+			)
+			if spec.QueueConfigAttributes != nil {
+				url, err = m.CreateQueueIfMissing(ctx, spec.Name, *spec.QueueConfigAttributes)
+			} else {
+				url, err = m.LookupQueueURL(ctx, spec.Name)
+			}
+			if err != nil {
+				return err
+			}
+			spec.URL = url
+		}
+		resolved[i] = spec
+	}
+
+	wg := sync.WaitGroup{}
+	for _, spec := range resolved {
+		wg.Add(1)
+		go func(spec QueueSpec) {
+			defer wg.Done()
+			m.pollQueue(ctx, spec)
+		}(spec)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (m *MultiQueueConsumer) pollQueue(ctx context.Context, spec QueueSpec) {
+	localCap := spec.Weight
+	if localCap <= 0 {
+		localCap = m.MaxHandlers
+	}
+	localSem := make(chan struct{}, localCap)
+	bo := newBackoff(100*time.Millisecond, 30*time.Second, 2)
+
+	wg := sync.WaitGroup{}
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		default:
+		}
+
+		result, stop := resilientReceive(ctx, m.svc, &sqs.ReceiveMessageInput{
+			QueueUrl:            &spec.URL,
+			MaxNumberOfMessages: spec.BatchSize,
+			WaitTimeSeconds:     spec.WaitSeconds,
+			VisibilityTimeout:   spec.VisibilityTimeout,
+		}, bo, m.OnError, func(format string, args ...interface{}) {
+			logger.Printf("%s: %s", spec.URL, fmt.Sprintf(format, args...))
+		})
+		if stop {
+			wg.Wait()
+			return
+		}
+		if result == nil {
+			continue
+		}
+
+		for i := range result.Messages {
+			msg := result.Messages[i]
+
+			if spec.Handler == nil {
+				logger.Printf("No handler registered for %s. Register one on the QueueSpec for custom handling", spec.URL)
+				continue
+			}
+
+			select {
+			case localSem <- struct{}{}:
+			case <-ctx.Done():
+				wg.Wait()
+				return
+			}
+			select {
+			case m.sem <- struct{}{}:
+			case <-ctx.Done():
+				<-localSem
+				wg.Wait()
+				return
+			}
+
+			wg.Add(1)
+			go func(msg types.Message) {
+				defer wg.Done()
+				defer func() { <-m.sem; <-localSem }()
+				spec.Handler(&msg)
+			}(msg)
+		}
+
+		if len(result.Messages) == 0 {
+			sleepCtx(ctx, time.Duration(spec.RunInterval)*time.Second)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package sqs
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// Publisher publishes typed payloads to SQS queues, marshaling them with the
+// configured Marshaler before sending.
+type Publisher struct {
+	svc       *sqs.Client
+	marshaler Marshaler
+}
+
+// NewPublisher builds a Publisher backed by awsCfg.
+func NewPublisher(awsCfg aws.Config, marshaler Marshaler) *Publisher {
+	return &Publisher{
+		svc:       sqs.NewFromConfig(awsCfg),
+		marshaler: marshaler,
+	}
+}
+
+// Publish marshals payload and sends it to the queue at topic (a queue URL),
+// merging metadata in as plain String message attributes alongside whatever
+// attributes the Marshaler produced.
+func (p *Publisher) Publish(ctx context.Context, topic string, payload interface{}, metadata map[string]string) error {
+	body, attrs, err := p.marshaler.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	if attrs == nil {
+		attrs = make(map[string]types.MessageAttributeValue, len(metadata))
+	}
+	for k, v := range metadata {
+		attrs[k] = stringAttr(v)
+	}
+
+	_, err = p.svc.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:          &topic,
+		MessageBody:       &body,
+		MessageAttributes: attrs,
+	})
+
+	return err
+}
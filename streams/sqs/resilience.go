@@ -0,0 +1,146 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// errClass buckets a ReceiveMessage error into how the poll loop should react to it.
+type errClass int
+
+const (
+	// errTransient covers throttling, 5xx and other blips worth retrying.
+	errTransient errClass = iota
+	// errTerminal covers auth failures and a deleted queue - retrying won't help.
+	errTerminal
+	// errCancelled means ctx was cancelled; the poll loop should just exit.
+	errCancelled
+)
+
+// terminalErrorCodes are SQS/STS error codes that won't be resolved by retrying.
+var terminalErrorCodes = map[string]bool{
+	"QueueDoesNotExist":           true,
+	"AccessDenied":                true,
+	"AccessDeniedException":       true,
+	"InvalidClientTokenId":        true,
+	"AuthFailure":                 true,
+	"SignatureDoesNotMatch":       true,
+	"UnrecognizedClientException": true,
+}
+
+// classifyError decides whether err is worth retrying.
+func classifyError(ctx context.Context, err error) errClass {
+	if ctx.Err() != nil {
+		return errCancelled
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		if terminalErrorCodes[apiErr.ErrorCode()] {
+			return errTerminal
+		}
+	}
+
+	var respErr *smithyhttp.ResponseError
+	if errors.As(err, &respErr) && respErr.HTTPStatusCode() >= 500 {
+		return errTransient
+	}
+
+	// Unknown shape (network blip, timeout, throttling without a distinct
+	// HTTP code) - treat as transient so the loop keeps retrying.
+	return errTransient
+}
+
+// backoff is an exponential backoff with full jitter, resetting on success.
+type backoff struct {
+	initial time.Duration
+	max     time.Duration
+	factor  float64
+	attempt int
+}
+
+func newBackoff(initial, max time.Duration, factor float64) *backoff {
+	return &backoff{initial: initial, max: max, factor: factor}
+}
+
+func (b *backoff) next() time.Duration {
+	d := time.Duration(float64(b.initial) * math.Pow(b.factor, float64(b.attempt)))
+	if d > b.max || d <= 0 {
+		d = b.max
+	}
+	b.attempt++
+
+	// Full jitter: uniform in [0, d].
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+func (b *backoff) reset() {
+	b.attempt = 0
+}
+
+// resilientReceive issues a ReceiveMessage call and applies the shared
+// classify+backoff+onError policy on failure, so every poll loop (Config.Poll,
+// Subscriber.Subscribe, MultiQueueConsumer.pollQueue) reacts to errors the
+// same way instead of hot-looping forever on a bad IAM policy or a deleted queue.
+//
+// stop is true when the caller should give up polling entirely (a terminal
+// error, or ctx cancellation). Otherwise, a nil output means the error was
+// transient and already backed off - the caller should just retry.
+func resilientReceive(
+	ctx context.Context,
+	svc *sqs.Client,
+	input *sqs.ReceiveMessageInput,
+	bo *backoff,
+	onError func(error),
+	logf func(format string, args ...interface{}),
+) (output *sqs.ReceiveMessageOutput, stop bool) {
+	output, err := svc.ReceiveMessage(ctx, input)
+	if err == nil {
+		bo.reset()
+		return output, false
+	}
+
+	if onError != nil {
+		onError(err)
+	}
+
+	switch classifyError(ctx, err) {
+	case errCancelled:
+		logf("Context cancelled during ReceiveMessage")
+		return nil, true
+	case errTerminal:
+		logf("Terminal ReceiveMessageError, exiting: %s", err)
+		return nil, true
+	default:
+		wait := bo.next()
+		logf("Transient ReceiveMessageError, retrying in %s: %s", wait, err)
+		if sleepCtx(ctx, wait) {
+			return nil, true
+		}
+		return nil, false
+	}
+}
+
+// HealthCheck issues a lightweight GetQueueAttributes call, suitable for
+// wiring into a caller's /healthz endpoint.
+func (s *Config) HealthCheck(ctx context.Context) error {
+	if s.svc == nil {
+		return errors.New("no service connection")
+	}
+
+	_, err := s.svc.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       &s.URL,
+		AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameQueueArn},
+	})
+
+	return err
+}
@@ -1,6 +1,7 @@
 package sqs
 
 import (
+	"context"
 	"errors"
 	"log"
 	"os"
@@ -8,10 +9,10 @@ import (
 	"sync"
 	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 )
 
 var logger *log.Logger
@@ -22,24 +23,25 @@ func init() {
 
 // Config Wrapper for Config methods
 type Config struct {
-	AWSKey    string
-	AWSSecret string
-	AWSRegion string
+	// AWSKey, AWSSecret and MaxRetries are only consulted by the deprecated
+	// NewSQSFromEnv constructor. Callers using NewSQS should configure
+	// credentials and retries on the aws.Config they pass in instead.
+	AWSKey     string
+	AWSSecret  string
+	AWSRegion  string
+	MaxRetries int
 
 	// Poll from this SQS URL
 	URL string
 
-	// Maximum number of time to attempt AWS service connection
-	MaxRetries int
-
 	// Maximum number of messages to retrieve per batch
-	BatchSize int64
+	BatchSize int32
 
 	// The maximum poll time (0 <= 20)
-	WaitSeconds int64
+	WaitSeconds int32
 
 	// Once a message is received by a consumer, the maximum time in seconds till others can see this
-	VisibilityTimeout int64
+	VisibilityTimeout int32
 
 	// Poll only once and exit
 	RunOnce bool
@@ -53,22 +55,45 @@ type Config struct {
 	// BusyTimeout in seconds
 	BusyTimeout int
 
-	svc          *sqs.SQS
+	// CoalesceBatches opts Delete and ChangeVisibilityTimeout into buffering
+	// their requests and flushing them via DeleteBatch/ChangeVisibilityBatch
+	// every FlushInterval, or as soon as maxBatchEntries accumulate.
+	CoalesceBatches bool
+
+	// FlushInterval is how often buffered Delete/ChangeVisibilityTimeout
+	// calls are flushed when CoalesceBatches is set. Required (and must be
+	// positive) whenever CoalesceBatches is true; validated in NewSQS.
+	FlushInterval time.Duration
+
+	// OnBatchResult, if set, is called after each flush triggered by
+	// CoalesceBatches. op is "delete" or "changeVisibility".
+	OnBatchResult func(op string, successful, failed []string, err error)
+
+	// OnError, if set, is called with every ReceiveMessage error the poll
+	// loop hits, transient or terminal, before it decides how to react.
+	OnError func(error)
+
+	svc          *sqs.Client
 	handlerCount int
-	pollHandler  func(msg *sqs.Message)
+	pollHandler  func(msg *types.Message)
+
+	coalescerOnce *sync.Once
+	coalescer     *coalescer
 }
 
 // SQS An interface for SQS operations
 type SQS interface {
-	Poll()
-	Delete(msg *sqs.Message) error
-	Enqueue(msgBatch []*sqs.SendMessageBatchRequestEntry) error
-	RegisterPollHandler(pollHandler func(msg *sqs.Message))
-	ChangeVisibilityTimeout(msg *sqs.Message, seconds int64) bool
+	Poll(ctx context.Context)
+	Delete(ctx context.Context, msg *types.Message) error
+	Enqueue(ctx context.Context, msgBatch []types.SendMessageBatchRequestEntry) error
+	RegisterPollHandler(pollHandler func(msg *types.Message))
+	ChangeVisibilityTimeout(ctx context.Context, msg *types.Message, seconds int32) bool
 }
 
-// NewSQS Instantiate a SQS instance
-func NewSQS(opts Config) (SQS, error) {
+// NewSQS Instantiate a SQS instance backed by aws-sdk-go-v2. Callers own credential
+// resolution: build awsCfg with awsconfig.LoadDefaultConfig (optionally layering in
+// IMDS, SSO, or assume-role providers) and pass it in here.
+func NewSQS(ctx context.Context, awsCfg aws.Config, opts Config) (SQS, error) {
 	// Validate parameters
 	validateErr := validateOpts(opts)
 	if validateErr != nil {
@@ -76,43 +101,14 @@ func NewSQS(opts Config) (SQS, error) {
 		return nil, validateErr
 	}
 
-	// Validate creds
-	if opts.AWSKey != "" {
-		os.Setenv("AWS_ACCESS_KEY_ID", opts.AWSKey)
-	}
-	if opts.AWSSecret != "" {
-		os.Setenv("AWS_SECRET_ACCESS_KEY", opts.AWSSecret)
-	}
-
-	creds := credentials.NewEnvCredentials()
-	if _, err := creds.Get(); err != nil {
-		logger.Println("AWS Credential error", err)
-		return nil, errors.New("Invalid AWS credentials. Please make sure that `AWS_ACCESS_KEY_ID` and `AWS_SECRET_ACCESS_KEY` is present in the env")
-	}
-
-	// Create AWS Config
-	awsConfig := aws.NewConfig().WithRegion(opts.AWSRegion).WithMaxRetries(opts.MaxRetries).WithCredentials(creds)
-	if awsConfig == nil {
-		logger.Println("Invalid AWS Config")
-		return nil, errors.New("Something is wrong with your AWS config parameters")
-	}
-
-	// Establish a session
-	newSession := session.Must(session.NewSession(awsConfig))
-	if newSession == nil {
-		logger.Println("Unable to create session")
-		return nil, errors.New("Unable to create session")
-	}
-
-	// Create a service connection
-	svc := sqs.New(newSession)
+	svc := sqs.NewFromConfig(awsCfg)
 	if svc == nil {
 		logger.Println("Unable to connect to SQS")
 		return nil, errors.New("Unable to create a service connection with AWS SQS")
 	}
 
 	logger.Println("Fetching queue attributes")
-	if _, err := svc.GetQueueAttributes(&sqs.GetQueueAttributesInput{
+	if _, err := svc.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
 		QueueUrl: &opts.URL,
 	}); err != nil {
 		logger.Println("Unable to fetch queue attributes", err)
@@ -121,19 +117,56 @@ func NewSQS(opts Config) (SQS, error) {
 	logger.Println("Connected to Queue")
 
 	opts.svc = svc
+	opts.coalescerOnce = &sync.Once{}
 	return &opts, nil
 }
 
-// Poll for messages in the queue
-func (s *Config) Poll() {
+// NewSQSFromEnv Instantiate a SQS instance the legacy way, by mutating the
+// AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY env vars and loading the default
+// credential chain from them.
+//
+// Deprecated: build an aws.Config yourself (eg with awsconfig.LoadDefaultConfig)
+// and call NewSQS instead.
+func NewSQSFromEnv(ctx context.Context, opts Config) (SQS, error) {
+	if opts.AWSKey != "" {
+		os.Setenv("AWS_ACCESS_KEY_ID", opts.AWSKey)
+	}
+	if opts.AWSSecret != "" {
+		os.Setenv("AWS_SECRET_ACCESS_KEY", opts.AWSSecret)
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx,
+		awsconfig.WithRegion(opts.AWSRegion),
+		awsconfig.WithRetryMaxAttempts(opts.MaxRetries),
+	)
+	if err != nil {
+		logger.Println("Unable to load AWS config", err)
+		return nil, errors.New("Unable to load AWS config")
+	}
+
+	return NewSQS(ctx, awsCfg, opts)
+}
+
+// Poll for messages in the queue until ctx is cancelled or ReceiveMessage fails
+// with a terminal error. With RunOnce set, it returns after the first batch.
+func (s *Config) Poll(ctx context.Context) {
 	if s.svc == nil {
 		logger.Fatalln("No service connection")
 	}
 
 	wg := sync.WaitGroup{}
 	batch := 0
+	bo := newBackoff(100*time.Millisecond, 30*time.Second, 2)
 
 	for {
+		select {
+		case <-ctx.Done():
+			logger.Println("Context cancelled, waiting for in-flight handlers to finish")
+			wg.Wait()
+			return
+		default:
+		}
+
 		batch++
 		childLogger := log.New(os.Stdout, "(gq-sqs) batch-"+strconv.Itoa(batch), log.Lshortfile)
 
@@ -146,24 +179,28 @@ func (s *Config) Poll() {
 
 			// Since all handlers are busy, let's wait for BusyTimeout seconds
 			childLogger.Printf("Going to wait state for %d seconds", s.BusyTimeout)
-			<-time.After(time.Duration(s.BusyTimeout) * time.Second)
+			if cancelled := sleepCtx(ctx, time.Duration(s.BusyTimeout)*time.Second); cancelled {
+				wg.Wait()
+				return
+			}
 			continue
 		} else {
-			maxMsgs = int64(s.MaxHandlers - s.handlerCount)
+			maxMsgs = int32(s.MaxHandlers - s.handlerCount)
 			childLogger.Printf("Can accept a maximum of %d messages", maxMsgs)
 		}
 
-		result, err := s.svc.ReceiveMessage(&sqs.ReceiveMessageInput{
+		result, stop := resilientReceive(ctx, s.svc, &sqs.ReceiveMessageInput{
 			QueueUrl:            &s.URL,
-			MaxNumberOfMessages: &maxMsgs,
-			WaitTimeSeconds:     &s.WaitSeconds,
-			VisibilityTimeout:   &s.VisibilityTimeout,
-		})
-
-		// Retrieve error?
-		if err != nil {
-			childLogger.Println("ReceiveMessageError:", err)
-			break
+			MaxNumberOfMessages: maxMsgs,
+			WaitTimeSeconds:     s.WaitSeconds,
+			VisibilityTimeout:   s.VisibilityTimeout,
+		}, bo, s.OnError, childLogger.Printf)
+		if stop {
+			wg.Wait()
+			return
+		}
+		if result == nil {
+			continue
 		}
 
 		// Message log
@@ -174,7 +211,8 @@ func (s *Config) Poll() {
 		}
 
 		// Process messages
-		for _, msg := range result.Messages {
+		for i := range result.Messages {
+			msg := result.Messages[i]
 			if s.pollHandler == nil {
 				childLogger.Println("No Poll handler registered. Register a handler for custom handling")
 			} else {
@@ -182,7 +220,7 @@ func (s *Config) Poll() {
 				wg.Add(1)
 
 				go func(w *sync.WaitGroup, inst *Config) {
-					inst.pollHandler(msg)
+					inst.pollHandler(&msg)
 					w.Done()
 					inst.handlerCount--
 				}(&wg, s)
@@ -196,7 +234,7 @@ func (s *Config) Poll() {
 			break
 		} else {
 			childLogger.Printf("Waiting for %d seconds before polling for next batch", s.RunInterval)
-			<-time.After(time.Duration(s.RunInterval) * time.Second)
+			sleepCtx(ctx, time.Duration(s.RunInterval)*time.Second)
 		}
 
 		childLogger.Println("Finished polling")
@@ -206,28 +244,35 @@ func (s *Config) Poll() {
 }
 
 // Enqueue messages to SQS
-func (s *Config) Enqueue(msgBatch []*sqs.SendMessageBatchRequestEntry) error {
+func (s *Config) Enqueue(ctx context.Context, msgBatch []types.SendMessageBatchRequestEntry) error {
 	if s.svc == nil {
 		logger.Fatal("No service connection")
 	}
 
 	logger.Printf(`%d messages are processing`, len(msgBatch))
 
-	result, err := s.svc.SendMessageBatch(&sqs.SendMessageBatchInput{
+	result, err := s.svc.SendMessageBatch(ctx, &sqs.SendMessageBatchInput{
 		QueueUrl: &s.URL,
 		Entries:  msgBatch,
 	})
+	if err != nil {
+		return err
+	}
 
 	logger.Printf("%d: Successfully Processed", len(result.Successful))
 	logger.Printf("%d: Failed to process", len(result.Failed))
 
-	return err
+	return nil
 }
 
 // Delete a SQS message from the queue
-func (s *Config) Delete(msg *sqs.Message) error {
+func (s *Config) Delete(ctx context.Context, msg *types.Message) error {
+	if s.CoalesceBatches {
+		s.coalescerFor(ctx).enqueueDelete(msg)
+		return nil
+	}
 
-	_, err := s.svc.DeleteMessage(&sqs.DeleteMessageInput{
+	_, err := s.svc.DeleteMessage(ctx, &sqs.DeleteMessageInput{
 		QueueUrl:      &s.URL,
 		ReceiptHandle: msg.ReceiptHandle,
 	})
@@ -236,12 +281,12 @@ func (s *Config) Delete(msg *sqs.Message) error {
 }
 
 // RegisterPollHandler : A method to register a custom Poll Handling method
-func (s *Config) RegisterPollHandler(pollHandler func(msg *sqs.Message)) {
+func (s *Config) RegisterPollHandler(pollHandler func(msg *types.Message)) {
 	s.pollHandler = pollHandler
 }
 
 // ChangeVisibilityTimeout : Method to change visibility timeout of a message.
-func (s *Config) ChangeVisibilityTimeout(msg *sqs.Message, seconds int64) bool {
+func (s *Config) ChangeVisibilityTimeout(ctx context.Context, msg *types.Message, seconds int32) bool {
 	retVal := false
 	logger.Printf("change visibility timeout for message ID %s", *msg.MessageId)
 
@@ -250,32 +295,38 @@ func (s *Config) ChangeVisibilityTimeout(msg *sqs.Message, seconds int64) bool {
 		return retVal
 	}
 
-	strURL := &s.URL
-	receiptHandle := *msg.ReceiptHandle
-
-	changeMessageVisibilityInput := sqs.ChangeMessageVisibilityInput{}
-
-	changeMessageVisibilityInput.SetQueueUrl(*strURL)
-	changeMessageVisibilityInput.SetReceiptHandle(receiptHandle)
-	changeMessageVisibilityInput.SetVisibilityTimeout(seconds)
+	if s.CoalesceBatches {
+		s.coalescerFor(ctx).enqueueVisibilityChange(msg, seconds)
+		return true
+	}
 
-	out, err := s.svc.ChangeMessageVisibility(&changeMessageVisibilityInput)
+	_, err := s.svc.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          &s.URL,
+		ReceiptHandle:     msg.ReceiptHandle,
+		VisibilityTimeout: seconds,
+	})
 
 	if err == nil {
-		logger.Printf("changed visibility timeout success for %s", (*out).GoString())
+		logger.Printf("changed visibility timeout success for %s", *msg.MessageId)
 		retVal = true
 	} else {
-		logger.Printf("change visibility timeout failed: %s", (*out).GoString())
+		logger.Printf("change visibility timeout failed: %s", err)
 	}
 
 	return retVal
 }
 
-func validateOpts(opts Config) error {
-	if opts.AWSRegion == "" {
-		return errors.New("AWSRegion is required")
+// sleepCtx waits for d, returning early with true if ctx is cancelled first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return true
+	case <-time.After(d):
+		return false
 	}
+}
 
+func validateOpts(opts Config) error {
 	if opts.URL == "" {
 		return errors.New("A valid SQS URL is required")
 	}
@@ -292,5 +343,9 @@ func validateOpts(opts Config) error {
 		return errors.New("WaitSecond should be between 1-43200")
 	}
 
+	if opts.CoalesceBatches && opts.FlushInterval <= 0 {
+		return errors.New("FlushInterval is required and must be positive when CoalesceBatches is set")
+	}
+
 	return nil
 }
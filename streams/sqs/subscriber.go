@@ -0,0 +1,117 @@
+package sqs
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+// SubscriberConfig configures how a Subscriber receives messages.
+type SubscriberConfig struct {
+	// Maximum number of messages to retrieve per batch
+	BatchSize int32
+
+	// The maximum poll time (0 <= 20)
+	WaitSeconds int32
+
+	// Once a message is received, the maximum time in seconds till others can see it
+	VisibilityTimeout int32
+
+	// When a poll returns no messages, wait this many seconds before polling again
+	RunInterval int
+
+	// OnError, if set, is called with every ReceiveMessage error Subscribe hits.
+	OnError func(error)
+}
+
+// Subscriber receives typed messages from SQS queues, leaving Ack/Nack to the
+// caller instead of deleting on their behalf.
+type Subscriber struct {
+	svc         *sqs.Client
+	unmarshaler Unmarshaler
+	cfg         SubscriberConfig
+}
+
+// NewSubscriber builds a Subscriber backed by awsCfg.
+func NewSubscriber(awsCfg aws.Config, unmarshaler Unmarshaler, cfg SubscriberConfig) *Subscriber {
+	return &Subscriber{
+		svc:         sqs.NewFromConfig(awsCfg),
+		unmarshaler: unmarshaler,
+		cfg:         cfg,
+	}
+}
+
+// Subscribe polls topic (a queue URL) and streams received messages on the
+// returned channel until ctx is cancelled or ReceiveMessage fails with a
+// terminal error, at which point the channel is closed.
+func (s *Subscriber) Subscribe(ctx context.Context, topic string) (<-chan *Message, error) {
+	out := make(chan *Message)
+
+	go func() {
+		defer close(out)
+
+		bo := newBackoff(100*time.Millisecond, 30*time.Second, 2)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			result, stop := resilientReceive(ctx, s.svc, &sqs.ReceiveMessageInput{
+				QueueUrl:            &topic,
+				MaxNumberOfMessages: s.cfg.BatchSize,
+				WaitTimeSeconds:     s.cfg.WaitSeconds,
+				VisibilityTimeout:   s.cfg.VisibilityTimeout,
+			}, bo, s.cfg.OnError, logger.Printf)
+			if stop {
+				return
+			}
+			if result == nil {
+				continue
+			}
+
+			for i := range result.Messages {
+				raw := result.Messages[i]
+
+				metadata := make(map[string]string, len(raw.MessageAttributes))
+				for k, v := range raw.MessageAttributes {
+					if v.StringValue != nil {
+						metadata[k] = *v.StringValue
+					}
+				}
+
+				msg := &Message{
+					ID:            *raw.MessageId,
+					Body:          []byte(*raw.Body),
+					Metadata:      metadata,
+					queueURL:      topic,
+					receiptHandle: *raw.ReceiptHandle,
+					rawAttrs:      raw.MessageAttributes,
+					svc:           s.svc,
+				}
+
+				select {
+				case out <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			if len(result.Messages) == 0 {
+				sleepCtx(ctx, time.Duration(s.cfg.RunInterval)*time.Second)
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// Unmarshal decodes msg's body (and whatever attributes the Marshaler wrote
+// on publish) into v, using the Subscriber's configured Unmarshaler.
+func (s *Subscriber) Unmarshal(msg *Message, v interface{}) error {
+	return s.unmarshaler.Unmarshal(string(msg.Body), msg.rawAttrs, v)
+}